@@ -0,0 +1,35 @@
+// Package config defines Roller's runtime configuration.
+package config
+
+import "time"
+
+// Config is the Roller's runtime configuration, wired straight into
+// core.Roller.
+type Config struct {
+	ScrollURL  string `json:"scroll_url"`
+	DBPath     string `json:"db_path"`
+	SecretKey  string `json:"secret_key"`
+	RollerName string `json:"roller_name"`
+
+	// PingInterval overrides the default websocket heartbeat interval.
+	PingInterval time.Duration `json:"ping_interval"`
+	// ReauthInterval overrides how often Roller re-proves its identity to
+	// Scroll.
+	ReauthInterval time.Duration `json:"reauth_interval"`
+	// PendingTimeout overrides how long a submitted-but-unacked proof must
+	// have sat in the pending bucket for Roller to requeue it at startup.
+	// Reconnects use a fixed shorter threshold, and the pending bucket is not
+	// rechecked periodically.
+	PendingTimeout time.Duration `json:"pending_timeout"`
+
+	Prover *ProverConfig `json:"prover"`
+}
+
+// ProverConfig configures how Roller drives its local prover.
+type ProverConfig struct {
+	// Concurrency is the number of ProveLoop workers. Defaults to 1.
+	Concurrency int `json:"concurrency"`
+	// MaxInFlight bounds how many proofs may be in flight across all workers
+	// at once. Defaults to 4.
+	MaxInFlight int `json:"max_in_flight"`
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -21,25 +22,116 @@ import (
 
 var (
 	writeWait = time.Second + readWait
-	// consider ping message
+	// readWait is refreshed on every pong (or ping) from Scroll, so it only
+	// needs to comfortably exceed defaultPingInterval.
 	readWait = time.Minute * 30
+	// defaultPingInterval is used when cfg.PingInterval is unset.
+	defaultPingInterval = time.Second * 30
 	// retry scroll
 	retryWait = time.Second * 10
 	// net normal close
 	errNormalClose = errors.New("use of closed network connection")
 )
 
+const (
+	// maxMissedPongs is the number of unanswered pings tolerated before the
+	// connection is considered dead and torn down.
+	maxMissedPongs = 3
+	// defaultConcurrency and defaultMaxInFlight are used when cfg.Prover
+	// leaves Concurrency/MaxInFlight unset.
+	defaultConcurrency = 1
+	defaultMaxInFlight = 4
+	// defaultReauthInterval is used when cfg.ReauthInterval is unset.
+	defaultReauthInterval = time.Minute * 15
+	// reauthAckTimeout bounds how long we wait for Scroll's AuthAck before
+	// treating a re-auth attempt as failed.
+	reauthAckTimeout = time.Second * 10
+	// defaultPendingTimeout is used when cfg.PendingTimeout is unset. At
+	// startup, Run requeues proofs that have sat in the pending bucket longer
+	// than this without a ProofAck; the pending bucket is not rechecked
+	// periodically after that.
+	defaultPendingTimeout = time.Minute * 5
+	// reconnectRequeueTimeout is used instead of cfg.PendingTimeout right
+	// after a reconnect: the old conn is already known dead, so there's no
+	// reason to wait out the full pending timeout before redelivering.
+	// It only applies to proofs whose worker is gone too - a trace still
+	// held by a live proveWorker is skipped regardless of age (see
+	// Roller.inFlight), since that worker will submit it itself once done.
+	reconnectRequeueTimeout = time.Second * 2
+)
+
+// controlFrame is a websocket control message (ping/pong) queued for writePump.
+type controlFrame struct {
+	messageType int
+	data        []byte
+}
+
+// authAckResult is a decoded AuthAckMsg paired with the nonce it answers, so
+// reauth can match it against the attempt it's waiting for.
+type authAckResult struct {
+	nonce uint64
+	err   error
+}
+
 // Roller contains websocket conn to Scroll, Stack, unix-socket to ipc-prover.
+//
+// The conn itself is only ever read and written by two goroutines (others at
+// most Close it): HandleScroll is the
+// sole reader (readPump), and writePump is the sole writer - gorilla/websocket
+// forbids concurrent writers, and swapping r.conn directly from multiple
+// goroutines on reconnect is itself a race, so access always goes through
+// getConn/setConn.
 type Roller struct {
-	cfg    *config.Config
-	conn   *websocket.Conn
-	stack  *store.Stack
-	prover *prover.Prover
+	cfg     *config.Config
+	connVal atomic.Value // *websocket.Conn
+	stack   *store.Stack
+	prover  *prover.Prover
+
+	// lastTraceID is an in-memory cache of the ID of the most recent trace we
+	// persisted, used to resume a session after a reconnect instead of
+	// re-registering blind. store.Stack is the source of truth - it's seeded
+	// from there in NewRoller and written through on every persistTrace, so a
+	// process restart resumes from the right point too.
+	lastTraceID uint64
+	missedPongs int64
+
+	// outboundCh carries serialized data frames (Register, Proof, ...) and
+	// controlCh carries pings/pongs; writePump is the only consumer of both,
+	// and therefore the only caller of conn.WriteMessage/WriteControl.
+	outboundCh chan []byte
+	controlCh  chan controlFrame
+	wg         sync.WaitGroup
+
+	// inFlight holds the trace ID of every proof a proveWorker currently has
+	// popped and is actively computing (from Pop until the resulting Proof
+	// is enqueued). requeuePending consults it so a reconnect doesn't
+	// requeue a trace a live worker still holds, which would otherwise let
+	// a second worker pop and redundantly recompute it.
+	inFlight sync.Map // trace ID (uint64) -> struct{}
+
+	// reauthNonce is a monotonically increasing attempt id stamped on every
+	// Reauth so reauth can tell its own AuthAck apart from a stale one.
+	reauthNonce uint64
+	// authAckCh delivers the AuthAcks Scroll sends (for Register as well as
+	// Reauth) to whichever goroutine is waiting on it; handleAuthAck is the
+	// only sender, and drops an ack when the one-slot buffer is already full. reauth matches on AuthAckMsg.Nonce rather than trusting
+	// the first thing that arrives, since Register's ack (always nonce 0)
+	// would otherwise sit buffered here until the first reauth tick.
+	authAckCh chan authAckResult
 
 	isClosed int64
+	closeErr error
 	stopChan chan struct{}
 }
 
+func (r *Roller) getConn() *websocket.Conn {
+	return r.connVal.Load().(*websocket.Conn)
+}
+
+func (r *Roller) setConn(conn *websocket.Conn) {
+	r.connVal.Store(conn)
+}
+
 // NewRoller new a Roller object.
 func NewRoller(cfg *config.Config) (*Roller, error) {
 	// Get stack db handler
@@ -61,19 +153,44 @@ func NewRoller(cfg *config.Config) (*Roller, error) {
 		return nil, err
 	}
 
-	return &Roller{
-		cfg:      cfg,
-		conn:     conn,
-		stack:    stackDb,
-		prover:   pver,
-		stopChan: make(chan struct{}),
-	}, nil
+	// Pick up the resume point from a previous run, so a process restart
+	// (as opposed to just a mid-run reconnect) doesn't fall back to a fresh
+	// Register and lose or duplicate traces.
+	lastTraceID, err := stackDb.LastTraceID()
+	if err != nil {
+		return nil, err
+	}
+
+	roller := &Roller{
+		cfg:         cfg,
+		stack:       stackDb,
+		prover:      pver,
+		lastTraceID: lastTraceID,
+		outboundCh:  make(chan []byte, 64),
+		controlCh:   make(chan controlFrame, 4),
+		authAckCh:   make(chan authAckResult, 1),
+		stopChan:    make(chan struct{}),
+	}
+	roller.setConn(conn)
+	roller.configureConn()
+
+	return roller, nil
 }
 
 // Run runs Roller.
 func (r *Roller) Run() error {
+	go r.writePump()
+
+	// A process restart is resumed exactly like a mid-run reconnect: if
+	// NewRoller seeded lastTraceID from a previous session, report it via
+	// resume instead of registering fresh, so Scroll redelivers only what
+	// we're missing instead of replaying, or silently dropping, traces.
+	reconnect := r.Register
+	if atomic.LoadUint64(&r.lastTraceID) != 0 {
+		reconnect = r.resume
+	}
 	log.Info("start to register to scroll")
-	if err := r.Register(); err != nil {
+	if err := reconnect(); err != nil {
 		log.Crit("register to scroll failed", "error", err)
 	}
 	log.Info("register to scroll successfully!")
@@ -81,15 +198,235 @@ func (r *Roller) Run() error {
 		r.HandleScroll()
 		r.Close()
 	}()
+	go r.pingLoop()
+	go r.reauthLoop()
+
+	// Requeue what a previous run that crashed (or otherwise exited) left in
+	// the pending bucket unacked, once it's older than pendingTimeout.
+	r.requeuePending(r.pendingTimeout())
 
 	return r.ProveLoop()
 }
 
+// pendingTimeout returns cfg.PendingTimeout, falling back to
+// defaultPendingTimeout when unset.
+func (r *Roller) pendingTimeout() time.Duration {
+	if r.cfg.PendingTimeout <= 0 {
+		return defaultPendingTimeout
+	}
+	return r.cfg.PendingTimeout
+}
+
+// requeuePending moves pending proofs older than timeout back onto the
+// traces stack so a lost write (dropped conn, crash before ack) doesn't
+// silently lose the trace. A trace a proveWorker still has in flight is
+// never requeued, regardless of age.
+func (r *Roller) requeuePending(timeout time.Duration) {
+	skip := func(id uint64) bool {
+		_, ok := r.inFlight.Load(id)
+		return ok
+	}
+	if err := r.stack.Requeue(timeout, skip); err != nil {
+		log.Error("failed to requeue pending proofs", "error", err)
+	}
+}
+
+// writePump is the sole goroutine permitted to write to the conn. It
+// replaces the direct WriteMessage/WriteControl calls that used to happen
+// from Register, resume, prove and pingLoop - all different goroutines
+// racing on the same conn, which gorilla/websocket explicitly forbids.
+func (r *Roller) writePump() {
+	for {
+		select {
+		case <-r.stopChan:
+			return
+		case msgByt := <-r.outboundCh:
+			conn := r.getConn()
+			_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.BinaryMessage, msgByt); err != nil {
+				log.Error("failed to write to scroll", "error", err)
+			}
+		case frame := <-r.controlCh:
+			conn := r.getConn()
+			if err := conn.WriteControl(frame.messageType, frame.data, time.Now().Add(writeWait)); err != nil {
+				log.Error("failed to write control frame", "error", err)
+			}
+		}
+	}
+}
+
+// enqueue hands a serialized frame to writePump, the only goroutine allowed
+// to write to the conn.
+func (r *Roller) enqueue(msgByt []byte) error {
+	select {
+	case r.outboundCh <- msgByt:
+		return nil
+	case <-r.stopChan:
+		return errNormalClose
+	}
+}
+
+// reauthLoop periodically re-proves our identity to Scroll so a long-lived
+// connection can't outlive a key rotation: AuthMessage.Timestamp is only
+// validated once, at registration, otherwise. If Scroll rejects a re-auth
+// (e.g. our public key was revoked) or never acks it, the connection is torn
+// down rather than left to silently submit proofs Scroll will reject.
+func (r *Roller) reauthLoop() {
+	interval := r.cfg.ReauthInterval
+	if interval <= 0 {
+		interval = defaultReauthInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stopChan:
+			return
+		case <-ticker.C:
+			if err := r.reauth(); err != nil {
+				log.Error("re-authentication failed, closing connection", "error", err)
+				r.closeWithError(fmt.Errorf("re-auth failed: %w", err))
+				return
+			}
+			log.Info("re-authenticated with scroll successfully!")
+		}
+	}
+}
+
+func (r *Roller) reauth() error {
+	nonce := atomic.AddUint64(&r.reauthNonce, 1)
+
+	authMsg, err := r.signedAuthMessage()
+	if err != nil {
+		return err
+	}
+	authMsg.Nonce = nonce
+
+	msgByt, err := MakeMsgByt(Reauth, authMsg)
+	if err != nil {
+		return err
+	}
+
+	if err := r.enqueue(msgByt); err != nil {
+		return err
+	}
+
+	deadline := time.After(reauthAckTimeout)
+	for {
+		select {
+		case ack := <-r.authAckCh:
+			if ack.nonce != nonce {
+				// A stale ack - either Register's (always nonce 0) still
+				// sitting in the channel, or a late answer to a previous
+				// Reauth attempt. Keep waiting for the one that actually
+				// answers this attempt.
+				continue
+			}
+			return ack.err
+		case <-deadline:
+			return fmt.Errorf("timed out waiting for auth ack from scroll")
+		case <-r.stopChan:
+			return errNormalClose
+		}
+	}
+}
+
+// configureConn wires up ping/pong handling on the current conn and resets
+// the missed-pong counter. Must be called every time the conn is replaced.
+func (r *Roller) configureConn() {
+	atomic.StoreInt64(&r.missedPongs, 0)
+	conn := r.getConn()
+	conn.SetPongHandler(func(string) error {
+		atomic.StoreInt64(&r.missedPongs, 0)
+		return r.getConn().SetReadDeadline(time.Now().Add(readWait))
+	})
+	conn.SetPingHandler(func(appData string) error {
+		_ = r.getConn().SetReadDeadline(time.Now().Add(readWait))
+		select {
+		case r.controlCh <- controlFrame{messageType: websocket.PongMessage, data: []byte(appData)}:
+		case <-r.stopChan:
+		}
+		return nil
+	})
+}
+
+// pingLoop periodically pings Scroll so a dead peer is detected well before
+// the read deadline would otherwise catch it. After maxMissedPongs
+// consecutive unanswered pings it force-closes the conn, which surfaces as a
+// read error in HandleScroll and falls through to mustRetryScroll; pingLoop
+// itself keeps running so it keeps watching the new conn once mustRetryScroll
+// reconnects and configureConn resets missedPongs.
+func (r *Roller) pingLoop() {
+	interval := r.cfg.PingInterval
+	if interval <= 0 {
+		interval = defaultPingInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stopChan:
+			return
+		case <-ticker.C:
+			if atomic.LoadInt64(&r.missedPongs) >= maxMissedPongs {
+				log.Error("scroll missed too many pongs, forcing reconnect")
+				_ = r.getConn().Close()
+				continue
+			}
+			atomic.AddInt64(&r.missedPongs, 1)
+			select {
+			case r.controlCh <- controlFrame{messageType: websocket.PingMessage}:
+			case <-r.stopChan:
+				return
+			}
+		}
+	}
+}
+
 // Register registers Roller to the Scroll through Websocket.
 func (r *Roller) Register() error {
+	authMsg, err := r.signedAuthMessage()
+	if err != nil {
+		return err
+	}
+
+	msgByt, err := MakeMsgByt(Register, authMsg)
+	if err != nil {
+		return err
+	}
+
+	return r.enqueue(msgByt)
+}
+
+// resume re-establishes a previous session with Scroll, reporting the last
+// trace ID we persisted so Scroll only redelivers what we're missing instead
+// of replaying, or silently dropping, traces across a reconnect.
+func (r *Roller) resume() error {
+	authMsg, err := r.signedAuthMessage()
+	if err != nil {
+		return err
+	}
+
+	resumeMsg := &ResumeMessage{
+		Identity:    authMsg.Identity,
+		Signature:   authMsg.Signature,
+		LastTraceID: atomic.LoadUint64(&r.lastTraceID),
+	}
+
+	msgByt, err := MakeMsgByt(Resume, resumeMsg)
+	if err != nil {
+		return err
+	}
+
+	return r.enqueue(msgByt)
+}
+
+func (r *Roller) signedAuthMessage() (*AuthMessage, error) {
 	priv, err := crypto.HexToECDSA(r.cfg.SecretKey)
 	if err != nil {
-		return fmt.Errorf("generate private-key failed %v", err)
+		return nil, fmt.Errorf("generate private-key failed %v", err)
 	}
 	authMsg := &AuthMessage{
 		Identity: Identity{
@@ -100,29 +437,35 @@ func (r *Roller) Register() error {
 		Signature: "",
 	}
 
-	// Sign auth message
 	if err = authMsg.Sign(priv); err != nil {
-		return fmt.Errorf("Sign auth message failed %v", err)
-	}
-
-	msgByt, err := MakeMsgByt(Register, authMsg)
-	if err != nil {
-		return err
+		return nil, fmt.Errorf("Sign auth message failed %v", err)
 	}
-
-	return r.conn.WriteMessage(websocket.BinaryMessage, msgByt)
+	return authMsg, nil
 }
 
-// HandleScroll accepts block-traces from Scroll through the Websocket and store it into Stack.
+// HandleScroll is Roller's readPump: the sole goroutine that reads from the
+// conn. It accepts block-traces (and other messages) from Scroll through the
+// Websocket and stores traces into Stack.
 func (r *Roller) HandleScroll() {
 	for {
 		select {
 		case <-r.stopChan:
 			return
 		default:
-			_ = r.conn.SetWriteDeadline(time.Now().Add(writeWait))
-			_ = r.conn.SetReadDeadline(time.Now().Add(readWait))
-			if err := r.handMessage(); err != nil && !strings.Contains(err.Error(), errNormalClose.Error()) {
+			_ = r.getConn().SetReadDeadline(time.Now().Add(readWait))
+			if err := r.handMessage(); err != nil {
+				// closeWithError always sets isClosed before it closes the
+				// conn, so a read error while isClosed is set means this is
+				// closeWithError's own conn.Close() unblocking us during a
+				// real shutdown - nothing to reconnect. Checking the flag
+				// rather than matching on errNormalClose's text matters
+				// because pingLoop force-closes the conn the exact same
+				// way (same resulting error text) when it wants a
+				// reconnect, not a shutdown - string-matching couldn't tell
+				// the two apart and silently dropped the reconnect.
+				if atomic.LoadInt64(&r.isClosed) != 0 {
+					return
+				}
 				log.Error("handle scroll failed", "error", err)
 				r.mustRetryScroll()
 				continue
@@ -139,14 +482,23 @@ func (r *Roller) mustRetryScroll() {
 			log.Error("failed to connect scroll: ", "error", err)
 			time.Sleep(retryWait)
 		} else {
-			r.conn = conn
+			r.setConn(conn)
+			r.configureConn()
 			log.Info("re-connect to scroll successfully!")
 			break
 		}
 	}
+
+	// If we already had a session (i.e. we've persisted at least one trace),
+	// resume it instead of registering fresh so Scroll can redeliver only
+	// what we're missing rather than everything, or nothing, again.
+	reconnect := r.Register
+	if atomic.LoadUint64(&r.lastTraceID) != 0 {
+		reconnect = r.resume
+	}
 	for {
 		log.Info("retry to register to scroll...")
-		err := r.Register()
+		err := reconnect()
 		if err != nil {
 			log.Error("register to scroll failed", "error", err)
 			time.Sleep(retryWait)
@@ -156,52 +508,160 @@ func (r *Roller) mustRetryScroll() {
 		}
 	}
 
+	// Any proof that was popped into pending but never acked before the
+	// disconnect (submitted-but-dropped, or never submitted at all) goes
+	// back onto the traces stack so ProveLoop picks it up again. The old
+	// conn is already known dead, so use a much shorter threshold than
+	// cfg.PendingTimeout instead of waiting it out.
+	r.requeuePending(reconnectRequeueTimeout)
 }
 
-// ProveLoop keep popping the block-traces from Stack and sends it to rust-prover for loop.
-func (r *Roller) ProveLoop() (err error) {
+// proverLimits returns cfg's Concurrency/MaxInFlight, falling back to
+// defaultConcurrency/defaultMaxInFlight for anything unset or non-positive.
+// cfg may be nil - a Config that doesn't set Prover (zero-value struct, or
+// JSON that omits "prover") should just fall back to the defaults below
+// instead of panicking on a nil dereference.
+func proverLimits(cfg *config.ProverConfig) (concurrency, maxInFlight int) {
+	if cfg == nil {
+		cfg = &config.ProverConfig{}
+	}
+
+	concurrency = cfg.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	maxInFlight = cfg.MaxInFlight
+	if maxInFlight <= 0 {
+		maxInFlight = defaultMaxInFlight
+	}
+	return concurrency, maxInFlight
+}
+
+// ProveLoop spins up cfg.Prover.Concurrency workers, each popping traces from
+// Stack and proving them independently, bounded by cfg.Prover.MaxInFlight
+// proofs in flight at any given time.
+func (r *Roller) ProveLoop() error {
+	concurrency, maxInFlight := proverLimits(r.cfg.Prover)
+	inFlight := make(chan struct{}, maxInFlight)
+
+	r.wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go r.proveWorker(i, inFlight)
+	}
+	r.wg.Wait()
+	// Workers only exit once stopChan is closed, and closeWithError records
+	// its cause before closing it.
+	return r.closeErr
+}
+
+func (r *Roller) proveWorker(id int, inFlight chan struct{}) {
+	defer r.wg.Done()
 	for {
 		select {
 		case <-r.stopChan:
-			return nil
-		default:
-			_ = r.conn.SetWriteDeadline(time.Now().Add(writeWait))
-			if err = r.prove(); err != nil {
-				if errors.Is(err, store.ErrEmpty) {
-					log.Debug("get empty trace", "error", err)
-					time.Sleep(time.Second * 3)
-					continue
-				}
-				if strings.Contains(err.Error(), errNormalClose.Error()) {
-					return nil
-				}
-				log.Error("prove failed", "error", err)
-			}
+			return
+		case inFlight <- struct{}{}:
+		}
+
+		err := r.prove(id, len(inFlight))
+		<-inFlight
+
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, store.ErrEmpty) {
+			log.Debug("get empty trace", "worker", id, "error", err)
+			time.Sleep(time.Second * 3)
+			continue
+		}
+		if strings.Contains(err.Error(), errNormalClose.Error()) {
+			return
 		}
+		log.Error("prove failed", "worker", id, "error", err)
 	}
 }
 
 func (r *Roller) handMessage() error {
-	mt, msg, err := r.conn.ReadMessage()
+	mt, payload, err := r.getConn().ReadMessage()
 	if err != nil {
 		return err
 	}
+	if mt != websocket.BinaryMessage {
+		return nil
+	}
 
-	switch mt {
-	case websocket.BinaryMessage:
-		if err = r.persistTrace(msg); err != nil {
-			return err
-		}
+	msg := &Msg{}
+	if err := json.Unmarshal(payload, msg); err != nil {
+		return err
+	}
+
+	switch msg.Type {
+	case BlockTrace:
+		return r.persistTrace(msg.Payload)
+	case AuthAck:
+		return r.handleAuthAck(msg.Payload)
+	case ProofAck:
+		return r.handleProofAck(msg.Payload)
+	default:
+		log.Error("message from Scroll illegal", "type", msg.Type)
+		return nil
+	}
+}
+
+// handleAuthAck reports Scroll's response to a Register or Reauth to
+// whichever goroutine is waiting in reauth; reauth itself is responsible for
+// matching ack.nonce against the attempt it sent, since this ack may answer
+// an earlier Reauth (or the initial Register) instead.
+func (r *Roller) handleAuthAck(payload []byte) error {
+	ack := &AuthAckMsg{}
+	if err := json.Unmarshal(payload, ack); err != nil {
+		return err
+	}
+
+	var ackErr error
+	if !ack.OK {
+		ackErr = fmt.Errorf("scroll rejected re-auth: %s", ack.Error)
+	}
+	select {
+	case r.authAckCh <- authAckResult{nonce: ack.Nonce, err: ackErr}:
+	default:
+		// The one-slot buffer still holds an earlier ack nobody has read
+		// yet (e.g. after an unsolicited or duplicate ack); drop this one.
 	}
 	return nil
 }
 
-func (r *Roller) prove() error {
+// handleProofAck acks a proof Scroll has confirmed receiving, clearing it
+// from the pending bucket so Requeue never resurrects it.
+func (r *Roller) handleProofAck(payload []byte) error {
+	ack := &ProofAckMsg{}
+	if err := json.Unmarshal(payload, ack); err != nil {
+		return err
+	}
+	if err := r.stack.Ack(ack.ID); err != nil {
+		log.Error("failed to ack proof", "block-id", ack.ID, "error", err)
+	}
+	return nil
+}
+
+func (r *Roller) prove(workerID int, inFlightCount int) error {
+	start := time.Now()
 	traces, err := r.stack.Pop()
 	if err != nil {
 		return err
 	}
-	log.Info("start to prove block", "block-id", traces.ID)
+	r.inFlight.Store(traces.ID, struct{}{})
+	defer r.inFlight.Delete(traces.ID)
+
+	// queueDepth is the backlog still waiting in store.Stack, as distinct
+	// from inFlightCount (proofs already popped and being worked on) - a
+	// growing queueDepth with inFlightCount pinned at MaxInFlight is the
+	// signal that the prover pool, not the websocket, is the bottleneck.
+	queueDepth, qerr := r.stack.Len()
+	if qerr != nil {
+		log.Error("failed to read stack queue depth", "error", qerr)
+	}
+	log.Info("start to prove block", "worker", workerID, "block-id", traces.ID, "in-flight", inFlightCount, "queue-depth", queueDepth)
 
 	var proofMsg *ProofMsg
 	proof, err := r.prover.Prove(traces.Traces)
@@ -212,55 +672,73 @@ func (r *Roller) prove() error {
 			ID:     traces.ID,
 			Proof:  &AggProof{},
 		}
-		log.Error("prove block failed!", "block-id", traces.ID)
+		log.Error("prove block failed!", "worker", workerID, "block-id", traces.ID)
 	} else {
 		proofMsg = &ProofMsg{
 			Status: StatusOk,
 			ID:     traces.ID,
 			Proof:  proof,
 		}
-		log.Info("prove block successfully!", "block-id", traces.ID)
+		log.Info("prove block successfully!", "worker", workerID, "block-id", traces.ID, "duration", time.Since(start))
 	}
 
 	msgByt, err := MakeMsgByt(Proof, proofMsg)
 	if err != nil {
 		return err
 	}
-	return r.conn.WriteMessage(websocket.BinaryMessage, msgByt)
+	return r.enqueue(msgByt)
 }
 
 // Close closes the websocket connection.
 func (r *Roller) Close() {
-	if atomic.LoadInt64(&r.isClosed) == 1 {
+	r.closeWithError(nil)
+}
+
+// closeWithError tears down the Roller, recording err (if any) so ProveLoop
+// (and therefore Run) returns it and Err reports it, instead of quietly
+// accepting traces Scroll will reject, e.g. after a failed periodic re-auth.
+// HandleScroll simply stops.
+func (r *Roller) closeWithError(err error) {
+	if !atomic.CompareAndSwapInt64(&r.isClosed, 0, 1) {
 		return
 	}
-	atomic.StoreInt64(&r.isClosed, 1)
+	r.closeErr = err
 
 	close(r.stopChan)
+	// Wait for proveWorkers to notice stopChan and stop touching the stack
+	// and conn before we tear either down.
+	r.wg.Wait()
 	// Close scroll's ws
-	_ = r.conn.Close()
+	_ = r.getConn().Close()
 	// Close db
 	if err := r.stack.Close(); err != nil {
 		log.Error("failed to close bbolt db", "error", err)
 	}
 }
 
-func (r *Roller) persistTrace(byt []byte) error {
-	var msg = &Msg{}
-	err := json.Unmarshal(byt, msg)
-	if err != nil {
+// Err returns the error that caused Close, if any, so callers can tell a
+// clean shutdown from one forced by a protocol failure such as a rejected
+// re-auth.
+func (r *Roller) Err() error {
+	return r.closeErr
+}
+
+func (r *Roller) persistTrace(payload []byte) error {
+	var traces = &BlockTraces{}
+	if err := json.Unmarshal(payload, traces); err != nil {
 		return err
 	}
-	if msg.Type != BlockTrace {
-		log.Error("message from Scroll illegal")
-		return nil
+	log.Info("Accept BlockTrace from Scroll", "ID", traces.ID)
+	if err := r.stack.Push(traces); err != nil {
+		return err
 	}
-	var traces = &BlockTraces{}
-	if err := json.Unmarshal(msg.Payload, traces); err != nil {
+	// Persist the resume point itself, not just the traces, so a crash (as
+	// opposed to a mid-run reconnect) doesn't lose it.
+	if err := r.stack.SetLastTraceID(traces.ID); err != nil {
 		return err
 	}
-	log.Info("Accept BlockTrace from Scroll", "ID", traces.ID)
-	return r.stack.Push(traces)
+	atomic.StoreUint64(&r.lastTraceID, traces.ID)
+	return nil
 }
 
 // MakeMsgByt Marshals Msg to bytes.
@@ -274,4 +752,4 @@ func MakeMsgByt(msgTyp MsgType, payloadVal interface{}) ([]byte, error) {
 		Payload: payload,
 	}
 	return json.Marshal(msg)
-}
\ No newline at end of file
+}
@@ -0,0 +1,330 @@
+package core
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/scroll-tech/go-ethereum/crypto"
+
+	"scroll-tech/go-roller/config"
+	"scroll-tech/go-roller/store"
+	. "scroll-tech/go-roller/types"
+)
+
+// newTestWSConn dials a throwaway websocket test server and hands the
+// server-side conn to onServer (run in its own goroutine), so a test can
+// script both ends - e.g. to observe what writePump/pingLoop actually put on
+// the wire - without a real Scroll to talk to.
+func newTestWSConn(t *testing.T, onServer func(*websocket.Conn)) *websocket.Conn {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		if onServer != nil {
+			onServer(conn)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial test server: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// TestPingLoopClosesConnAfterMaxMissedPongs covers the threshold a previous
+// fix (see the off-by-one this series shipped and corrected) already got
+// wrong once: pingLoop must force-close the conn once missedPongs reaches
+// maxMissedPongs.
+func TestPingLoopClosesConnAfterMaxMissedPongs(t *testing.T) {
+	conn := newTestWSConn(t, func(*websocket.Conn) {
+		// Never read anything back, so every ping goes unanswered and
+		// missedPongs only ever climbs.
+	})
+
+	r := &Roller{
+		cfg:       &config.Config{PingInterval: 5 * time.Millisecond},
+		controlCh: make(chan controlFrame, 4),
+		stopChan:  make(chan struct{}),
+	}
+	r.setConn(conn)
+
+	go r.pingLoop()
+	defer close(r.stopChan)
+
+	deadline := time.After(time.Second)
+	for atomic.LoadInt64(&r.missedPongs) < maxMissedPongs {
+		select {
+		case <-deadline:
+			t.Fatal("missedPongs never reached maxMissedPongs")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	// pingLoop closes the conn itself as soon as the threshold is crossed;
+	// a write against the now-closed conn should fail.
+	deadline = time.After(time.Second)
+	for {
+		if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatal("conn was never closed after maxMissedPongs unanswered pings")
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+// TestProverLimitsDefaults and friends cover the concurrency/max-in-flight
+// selection ProveLoop relies on to size its worker pool and bound how many
+// proofs may be in flight at once.
+func TestProverLimitsDefaults(t *testing.T) {
+	concurrency, maxInFlight := proverLimits(nil)
+	if concurrency != defaultConcurrency || maxInFlight != defaultMaxInFlight {
+		t.Fatalf("proverLimits(nil) = (%d, %d), want (%d, %d)", concurrency, maxInFlight, defaultConcurrency, defaultMaxInFlight)
+	}
+}
+
+func TestProverLimitsFromConfig(t *testing.T) {
+	concurrency, maxInFlight := proverLimits(&config.ProverConfig{Concurrency: 8, MaxInFlight: 16})
+	if concurrency != 8 || maxInFlight != 16 {
+		t.Fatalf("proverLimits = (%d, %d), want (8, 16)", concurrency, maxInFlight)
+	}
+}
+
+func TestProverLimitsIgnoresNonPositiveOverrides(t *testing.T) {
+	concurrency, maxInFlight := proverLimits(&config.ProverConfig{Concurrency: -1, MaxInFlight: 0})
+	if concurrency != defaultConcurrency || maxInFlight != defaultMaxInFlight {
+		t.Fatalf("proverLimits(non-positive) = (%d, %d), want defaults (%d, %d)", concurrency, maxInFlight, defaultConcurrency, defaultMaxInFlight)
+	}
+}
+
+// TestReauthIgnoresStaleAckAndMatchesOwnNonce covers the nonce-matching loop
+// reauth runs against authAckCh: a stale ack (Register's, always nonce 0, or
+// a previous Reauth attempt's) must not be mistaken for the answer to this
+// attempt.
+func TestReauthIgnoresStaleAckAndMatchesOwnNonce(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	r := &Roller{
+		cfg:        &config.Config{SecretKey: hex.EncodeToString(crypto.FromECDSA(priv))},
+		outboundCh: make(chan []byte, 1),
+		authAckCh:  make(chan authAckResult, 2),
+		stopChan:   make(chan struct{}),
+	}
+
+	resultCh := make(chan error, 1)
+	go func() { resultCh <- r.reauth() }()
+
+	// Recover the nonce this attempt actually stamped, rather than assuming
+	// it's 1 - reauthNonce is shared with any earlier Reauth attempts.
+	msgByt := <-r.outboundCh
+	msg := &Msg{}
+	if err := json.Unmarshal(msgByt, msg); err != nil {
+		t.Fatalf("unmarshal enqueued frame: %v", err)
+	}
+	authMsg := &AuthMessage{}
+	if err := json.Unmarshal(msg.Payload, authMsg); err != nil {
+		t.Fatalf("unmarshal auth message: %v", err)
+	}
+
+	r.authAckCh <- authAckResult{nonce: authMsg.Nonce - 1}
+	select {
+	case err := <-resultCh:
+		t.Fatalf("reauth returned early on a stale-nonce ack: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	wantErr := errors.New("scroll rejected re-auth: revoked")
+	r.authAckCh <- authAckResult{nonce: authMsg.Nonce, err: wantErr}
+	select {
+	case err := <-resultCh:
+		if err == nil || err.Error() != wantErr.Error() {
+			t.Fatalf("reauth() = %v, want %v", err, wantErr)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("reauth never returned after its matching ack arrived")
+	}
+}
+
+// TestEnqueueReturnsErrNormalCloseAfterStop and
+// TestEnqueueDeliversToOutboundCh cover enqueue, the only path Register,
+// resume, reauth and prove use to reach writePump.
+func TestEnqueueReturnsErrNormalCloseAfterStop(t *testing.T) {
+	r := &Roller{outboundCh: make(chan []byte), stopChan: make(chan struct{})}
+	close(r.stopChan)
+
+	if err := r.enqueue([]byte("x")); err != errNormalClose {
+		t.Fatalf("enqueue after stop = %v, want %v", err, errNormalClose)
+	}
+}
+
+func TestEnqueueDeliversToOutboundCh(t *testing.T) {
+	r := &Roller{outboundCh: make(chan []byte, 1), stopChan: make(chan struct{})}
+
+	if err := r.enqueue([]byte("hello")); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if got := <-r.outboundCh; string(got) != "hello" {
+		t.Fatalf("outboundCh got %q, want %q", got, "hello")
+	}
+}
+
+// TestWritePumpDispatchesDataAndControlFrames covers writePump's routing:
+// outboundCh frames must go out as a data WriteMessage and controlCh frames
+// as a WriteControl, since it's the only goroutine gorilla/websocket allows
+// to touch the conn at all.
+func TestWritePumpDispatchesDataAndControlFrames(t *testing.T) {
+	type received struct {
+		data []byte
+		ping bool
+	}
+	gotCh := make(chan received, 2)
+
+	conn := newTestWSConn(t, func(serverConn *websocket.Conn) {
+		serverConn.SetPingHandler(func(string) error {
+			gotCh <- received{ping: true}
+			return nil
+		})
+		for {
+			mt, payload, err := serverConn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if mt == websocket.BinaryMessage {
+				gotCh <- received{data: payload}
+			}
+		}
+	})
+
+	r := &Roller{
+		outboundCh: make(chan []byte, 1),
+		controlCh:  make(chan controlFrame, 1),
+		stopChan:   make(chan struct{}),
+	}
+	r.setConn(conn)
+	go r.writePump()
+	defer close(r.stopChan)
+
+	if err := r.enqueue([]byte("hello")); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	r.controlCh <- controlFrame{messageType: websocket.PingMessage}
+
+	var sawData, sawPing bool
+	deadline := time.After(time.Second)
+	for !sawData || !sawPing {
+		select {
+		case got := <-gotCh:
+			if got.ping {
+				sawPing = true
+			} else if string(got.data) == "hello" {
+				sawData = true
+			}
+		case <-deadline:
+			t.Fatalf("writePump dispatch incomplete: data=%v ping=%v", sawData, sawPing)
+		}
+	}
+}
+
+// TestPingLoopForcesReconnectAfterMaxMissedPongs wires pingLoop together with
+// HandleScroll and mustRetryScroll against a real test server, the way Run
+// does, and checks the end-to-end effect of missed pongs: a new connection
+// carrying a fresh Register frame, not just that the old conn's writes start
+// failing. TestPingLoopClosesConnAfterMaxMissedPongs alone couldn't catch a
+// prior bug here, where HandleScroll mistook the conn-closed error from
+// pingLoop's own watchdog for the one produced by a real shutdown and never
+// called mustRetryScroll at all.
+func TestPingLoopForcesReconnectAfterMaxMissedPongs(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	var connN int64
+	registerCh := make(chan MsgType, 1)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		conn, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		if atomic.AddInt64(&connN, 1) == 1 {
+			// The first connection: never read from it, so pings go
+			// unanswered and missedPongs climbs until pingLoop force-closes
+			// it.
+			return
+		}
+		// Any later connection is the reconnect pingLoop should have
+		// triggered - capture the first frame Roller sends on it.
+		_, payload, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		msg := &Msg{}
+		if err := json.Unmarshal(payload, msg); err == nil {
+			registerCh <- msg.Type
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http")
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial test server: %v", err)
+	}
+
+	stackDb, err := store.NewStack(filepath.Join(t.TempDir(), "stack.db"))
+	if err != nil {
+		t.Fatalf("NewStack: %v", err)
+	}
+	t.Cleanup(func() { _ = stackDb.Close() })
+
+	r := &Roller{
+		cfg: &config.Config{
+			ScrollURL:    wsURL,
+			SecretKey:    hex.EncodeToString(crypto.FromECDSA(priv)),
+			PingInterval: 5 * time.Millisecond,
+		},
+		stack:      stackDb,
+		outboundCh: make(chan []byte, 64),
+		controlCh:  make(chan controlFrame, 4),
+		authAckCh:  make(chan authAckResult, 1),
+		stopChan:   make(chan struct{}),
+	}
+	r.setConn(conn)
+	r.configureConn()
+
+	go r.writePump()
+	go r.HandleScroll()
+	go r.pingLoop()
+	defer close(r.stopChan)
+
+	select {
+	case typ := <-registerCh:
+		if typ != Register {
+			t.Fatalf("frame on the reconnected conn = %q, want %q", typ, Register)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no frame arrived on a new connection after missed pongs - pingLoop's forced close never triggered a reconnect")
+	}
+}
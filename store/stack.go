@@ -0,0 +1,211 @@
+// Package store persists block traces awaiting proof in a bbolt database, so
+// a roller restart doesn't lose traces Scroll has already delivered.
+package store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"scroll-tech/go-roller/types"
+)
+
+var (
+	tracesBucket  = []byte("traces")
+	pendingBucket = []byte("pending")
+	metaBucket    = []byte("meta")
+
+	lastTraceIDKey = []byte("last_trace_id")
+)
+
+// ErrEmpty is returned by Pop when there are no traces waiting to be proved.
+var ErrEmpty = errors.New("store: no traces to pop")
+
+// pendingEntry wraps a popped trace with the time it was popped, so Requeue
+// can tell how long it's been waiting for a ProofAck.
+type pendingEntry struct {
+	Traces   *types.BlockTraces `json:"traces"`
+	PoppedAt time.Time          `json:"popped_at"`
+}
+
+// Stack is a bbolt-backed FIFO of block traces awaiting proof, plus the
+// pending/ack bookkeeping that makes proof delivery at-least-once: Pop moves
+// a trace into the pending bucket instead of discarding it, Ack clears it out
+// once Scroll confirms receipt, and Requeue moves anything Scroll never
+// acked back onto the traces bucket.
+type Stack struct {
+	db *bbolt.DB
+}
+
+// NewStack opens (creating if needed) the bbolt database at path.
+func NewStack(path string) (*Stack, error) {
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, bucket := range [][]byte{tracesBucket, pendingBucket, metaBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &Stack{db: db}, nil
+}
+
+// Push appends traces to the back of the stack.
+func (s *Stack) Push(traces *types.BlockTraces) error {
+	byt, err := json.Marshal(traces)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(tracesBucket).Put(idKey(traces.ID), byt)
+	})
+}
+
+// Pop removes the oldest traces from the stack and moves it into the pending
+// bucket, where it stays until Ack or Requeue moves it on.
+func (s *Stack) Pop() (*types.BlockTraces, error) {
+	var traces *types.BlockTraces
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(tracesBucket)
+		k, v := b.Cursor().First()
+		if k == nil {
+			return ErrEmpty
+		}
+
+		traces = &types.BlockTraces{}
+		if err := json.Unmarshal(v, traces); err != nil {
+			return err
+		}
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+
+		entryByt, err := json.Marshal(&pendingEntry{Traces: traces, PoppedAt: time.Now()})
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(pendingBucket).Put(k, entryByt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return traces, nil
+}
+
+// Ack removes a proof from the pending bucket once Scroll has confirmed
+// receiving it.
+func (s *Stack) Ack(id uint64) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(pendingBucket).Delete(idKey(id))
+	})
+}
+
+// Requeue moves every pending entry popped more than olderThan ago back onto
+// the traces bucket, so a proof that was submitted but never acked (dropped
+// write, crash or reconnect mid-flight) isn't lost for good. skip, if
+// non-nil, is consulted for every pending ID regardless of age - an ID it
+// reports true for is left in the pending bucket, e.g. because a live worker
+// is still actively proving it and would otherwise collide with a second
+// worker popping the same trace back off the traces bucket.
+func (s *Stack) Requeue(olderThan time.Duration, skip func(id uint64) bool) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		pending := tx.Bucket(pendingBucket)
+		traces := tx.Bucket(tracesBucket)
+		cutoff := time.Now().Add(-olderThan)
+
+		var stale [][]byte
+		err := pending.ForEach(func(k, v []byte) error {
+			if skip != nil && skip(binary.BigEndian.Uint64(k)) {
+				return nil
+			}
+			entry := &pendingEntry{}
+			if err := json.Unmarshal(v, entry); err != nil {
+				return err
+			}
+			if entry.PoppedAt.Before(cutoff) {
+				stale = append(stale, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range stale {
+			entry := &pendingEntry{}
+			if err := json.Unmarshal(pending.Get(k), entry); err != nil {
+				return err
+			}
+			tracesByt, err := json.Marshal(entry.Traces)
+			if err != nil {
+				return err
+			}
+			if err := traces.Put(k, tracesByt); err != nil {
+				return err
+			}
+			if err := pending.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Len returns the number of traces waiting to be popped, i.e. the backlog
+// depth, as counted by bbolt's bucket stats, which walk every page of the
+// traces bucket.
+func (s *Stack) Len() (int, error) {
+	var n int
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		n = tx.Bucket(tracesBucket).Stats().KeyN
+		return nil
+	})
+	return n, err
+}
+
+// LastTraceID returns the ID of the most recently persisted trace, or 0 if
+// none has been recorded yet, so Roller can resume a session across a
+// process restart rather than only across a mid-run reconnect.
+func (s *Stack) LastTraceID() (uint64, error) {
+	var id uint64
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket(metaBucket).Get(lastTraceIDKey)
+		if v == nil {
+			return nil
+		}
+		id = binary.BigEndian.Uint64(v)
+		return nil
+	})
+	return id, err
+}
+
+// SetLastTraceID persists the ID of the most recently persisted trace.
+func (s *Stack) SetLastTraceID(id uint64) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(metaBucket).Put(lastTraceIDKey, idKey(id))
+	})
+}
+
+// Close closes the underlying bbolt database.
+func (s *Stack) Close() error {
+	return s.db.Close()
+}
+
+func idKey(id uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, id)
+	return buf
+}
@@ -0,0 +1,163 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"scroll-tech/go-roller/types"
+)
+
+func newTestStack(t *testing.T) *Stack {
+	t.Helper()
+	s, err := NewStack(filepath.Join(t.TempDir(), "stack.db"))
+	if err != nil {
+		t.Fatalf("NewStack: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestPopMovesToPending(t *testing.T) {
+	s := newTestStack(t)
+	if err := s.Push(&types.BlockTraces{ID: 1}); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+
+	traces, err := s.Pop()
+	if err != nil {
+		t.Fatalf("Pop: %v", err)
+	}
+	if traces.ID != 1 {
+		t.Fatalf("Pop returned ID %d, want 1", traces.ID)
+	}
+
+	// The trace moved into the pending bucket rather than staying on the
+	// traces stack, so a second Pop should find nothing.
+	if _, err := s.Pop(); err != ErrEmpty {
+		t.Fatalf("second Pop = %v, want ErrEmpty", err)
+	}
+}
+
+func TestAckRemovesFromPending(t *testing.T) {
+	s := newTestStack(t)
+	if err := s.Push(&types.BlockTraces{ID: 1}); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if _, err := s.Pop(); err != nil {
+		t.Fatalf("Pop: %v", err)
+	}
+	if err := s.Ack(1); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+
+	// Acked entries are gone for good - even a Requeue with a zero threshold
+	// shouldn't resurrect them.
+	if err := s.Requeue(0, nil); err != nil {
+		t.Fatalf("Requeue: %v", err)
+	}
+	if _, err := s.Pop(); err != ErrEmpty {
+		t.Fatalf("Pop after Ack+Requeue = %v, want ErrEmpty", err)
+	}
+}
+
+func TestRequeueOnlyMovesStaleEntries(t *testing.T) {
+	s := newTestStack(t)
+	if err := s.Push(&types.BlockTraces{ID: 1}); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if _, err := s.Pop(); err != nil { // ID 1 -> pending
+		t.Fatalf("Pop: %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	// Fresh pending entries aren't stale yet; Requeue shouldn't touch them.
+	if err := s.Requeue(time.Hour, nil); err != nil {
+		t.Fatalf("Requeue: %v", err)
+	}
+	if _, err := s.Pop(); err != ErrEmpty {
+		t.Fatalf("Pop after Requeue(not-yet-stale) = %v, want ErrEmpty", err)
+	}
+
+	// Once it's older than the cutoff, it moves back onto the traces stack.
+	if err := s.Requeue(10 * time.Millisecond, nil); err != nil {
+		t.Fatalf("Requeue: %v", err)
+	}
+	traces, err := s.Pop()
+	if err != nil {
+		t.Fatalf("Pop after Requeue(stale): %v", err)
+	}
+	if traces.ID != 1 {
+		t.Fatalf("Pop after Requeue returned ID %d, want 1", traces.ID)
+	}
+}
+
+func TestRequeueSkipsEntriesSkipReturnsTrueFor(t *testing.T) {
+	s := newTestStack(t)
+	if err := s.Push(&types.BlockTraces{ID: 1}); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if _, err := s.Pop(); err != nil { // ID 1 -> pending
+		t.Fatalf("Pop: %v", err)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	// Even though the entry is stale enough to requeue, skip vetoes it - as
+	// if a live worker still held the trace and would otherwise collide with
+	// whatever pops it back off the traces bucket.
+	if err := s.Requeue(time.Millisecond, func(id uint64) bool { return id == 1 }); err != nil {
+		t.Fatalf("Requeue: %v", err)
+	}
+	if _, err := s.Pop(); err != ErrEmpty {
+		t.Fatalf("Pop after Requeue(skipped) = %v, want ErrEmpty", err)
+	}
+
+	// Once skip no longer vetoes it, it moves back as usual.
+	if err := s.Requeue(time.Millisecond, func(id uint64) bool { return false }); err != nil {
+		t.Fatalf("Requeue: %v", err)
+	}
+	if _, err := s.Pop(); err != nil {
+		t.Fatalf("Pop after Requeue(not-skipped): %v", err)
+	}
+}
+
+func TestLenCountsOnlyUnpoppedTraces(t *testing.T) {
+	s := newTestStack(t)
+
+	if n, err := s.Len(); err != nil || n != 0 {
+		t.Fatalf("Len on fresh stack = (%d, %v), want (0, nil)", n, err)
+	}
+	if err := s.Push(&types.BlockTraces{ID: 1}); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if err := s.Push(&types.BlockTraces{ID: 2}); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if n, err := s.Len(); err != nil || n != 2 {
+		t.Fatalf("Len after two pushes = (%d, %v), want (2, nil)", n, err)
+	}
+
+	// Pop moves one trace into pending, off the traces bucket Len counts.
+	if _, err := s.Pop(); err != nil {
+		t.Fatalf("Pop: %v", err)
+	}
+	if n, err := s.Len(); err != nil || n != 1 {
+		t.Fatalf("Len after Pop = (%d, %v), want (1, nil)", n, err)
+	}
+}
+
+func TestLastTraceIDPersists(t *testing.T) {
+	s := newTestStack(t)
+
+	if id, err := s.LastTraceID(); err != nil || id != 0 {
+		t.Fatalf("LastTraceID on fresh stack = (%d, %v), want (0, nil)", id, err)
+	}
+	if err := s.SetLastTraceID(42); err != nil {
+		t.Fatalf("SetLastTraceID: %v", err)
+	}
+	if id, err := s.LastTraceID(); err != nil || id != 42 {
+		t.Fatalf("LastTraceID = (%d, %v), want (42, nil)", id, err)
+	}
+}
@@ -0,0 +1,134 @@
+// Package types defines the messages exchanged between Roller and Scroll over
+// the websocket connection, and the pieces of those messages that get
+// persisted or re-derived locally (e.g. for signing and resume).
+package types
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/scroll-tech/go-ethereum/crypto"
+)
+
+// MsgType identifies the payload carried by a Msg.
+type MsgType string
+
+// Message types exchanged between Roller and Scroll.
+const (
+	// Register registers a roller with Scroll for the first time.
+	Register MsgType = "register"
+	// Resume re-establishes a previous session after a reconnect.
+	Resume MsgType = "resume"
+	// BlockTrace carries a block's traces from Scroll to the roller.
+	BlockTrace MsgType = "block_trace"
+	// Proof carries a completed proof from the roller back to Scroll.
+	Proof MsgType = "proof"
+	// Reauth periodically re-proves the roller's identity on a long-lived
+	// connection, so it can't outlive a key rotation.
+	Reauth MsgType = "reauth"
+	// AuthAck is Scroll's response to a Register or Reauth.
+	AuthAck MsgType = "auth_ack"
+	// ProofAck is Scroll's confirmation that it received a submitted Proof.
+	ProofAck MsgType = "proof_ack"
+)
+
+// Msg is the envelope every websocket frame is wrapped in; Payload is
+// unmarshalled further once Type has been switched on.
+type Msg struct {
+	Type    MsgType         `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// Identity identifies a roller to Scroll.
+type Identity struct {
+	Name      string `json:"name"`
+	Timestamp int64  `json:"timestamp"`
+	PublicKey string `json:"public_key"`
+}
+
+// Hash returns the bytes that Sign/Scroll's verification operate over.
+func (i *Identity) Hash() ([]byte, error) {
+	byt, err := json.Marshal(i)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(byt), nil
+}
+
+// AuthMessage is sent by Roller to authenticate with Scroll, both at initial
+// Register and on every subsequent Reauth.
+type AuthMessage struct {
+	Identity  Identity `json:"identity"`
+	Signature string   `json:"signature"`
+	// Nonce ties a Reauth to the AuthAckMsg Scroll sends back for it, so a
+	// roller waiting on one Reauth attempt can tell its ack apart from a
+	// stale one answering a previous attempt (or the initial Register, which
+	// always uses nonce 0). Not part of the signed payload.
+	Nonce uint64 `json:"nonce"`
+}
+
+// Sign signs the Identity with priv and fills in Signature.
+func (a *AuthMessage) Sign(priv *ecdsa.PrivateKey) error {
+	hash, err := a.Identity.Hash()
+	if err != nil {
+		return err
+	}
+	sig, err := crypto.Sign(hash, priv)
+	if err != nil {
+		return err
+	}
+	a.Signature = hex.EncodeToString(sig)
+	return nil
+}
+
+// ResumeMessage re-establishes a previous session, reporting the last trace
+// ID the roller persisted so Scroll only redelivers what was missed across
+// the reconnect instead of replaying, or silently dropping, traces.
+type ResumeMessage struct {
+	Identity    Identity `json:"identity"`
+	Signature   string   `json:"signature"`
+	LastTraceID uint64   `json:"last_trace_id"`
+}
+
+// AuthAckMsg is Scroll's response to a Register or Reauth.
+type AuthAckMsg struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+	// Nonce echoes back the AuthMessage.Nonce this ack answers.
+	Nonce uint64 `json:"nonce"`
+}
+
+// BlockTraces carries a block's traces as sent by Scroll.
+type BlockTraces struct {
+	ID     uint64          `json:"id"`
+	Traces json.RawMessage `json:"traces"`
+}
+
+// ProofStatus reports the outcome of proving a block.
+type ProofStatus int
+
+// Proof outcomes.
+const (
+	StatusOk ProofStatus = iota
+	StatusProofError
+)
+
+// AggProof is the aggregated proof produced by the local prover.
+type AggProof struct {
+	Proof []byte `json:"proof,omitempty"`
+}
+
+// ProofMsg carries the result of proving a block back to Scroll.
+type ProofMsg struct {
+	Status ProofStatus `json:"status"`
+	Error  string      `json:"error,omitempty"`
+	ID     uint64      `json:"id"`
+	Proof  *AggProof   `json:"proof"`
+}
+
+// ProofAckMsg is Scroll's confirmation that it received a submitted proof,
+// identifying the block it belongs to.
+type ProofAckMsg struct {
+	ID uint64 `json:"id"`
+}